@@ -31,6 +31,7 @@ import (
 	"bytes"
 	"encoding/base32"
 	"errors"
+	"fmt"
 	"sort"
 	"strings"
 	"unicode"
@@ -55,8 +56,70 @@ func init() {
 	goschtalt.DefaultOptions = append(goschtalt.DefaultOptions, goschtalt.WithEncoder(e))
 }
 
-// Encoder is a class for the yaml encoder.
-type Encoder struct{}
+// Encoder is a class for the yaml encoder.  The zero value is a fully usable,
+// default encoder - this is what init() registers with goschtalt.  Use New()
+// to customize its behavior.
+type Encoder struct {
+	indent        int
+	unsorted      bool
+	commentFormat func(meta.Origin) string
+	commentColumn int
+	originFilter  func([]meta.Origin) []meta.Origin
+}
+
+// Option configures an Encoder built with New().
+type Option interface {
+	apply(*Encoder)
+}
+
+type optionFunc func(*Encoder)
+
+func (f optionFunc) apply(e *Encoder) { f(e) }
+
+// New creates an Encoder configured with the provided options.
+func New(opts ...Option) Encoder {
+	var e Encoder
+	for _, opt := range opts {
+		opt.apply(&e)
+	}
+	return e
+}
+
+// WithIndent sets the number of spaces used per indentation level.  A value
+// of 0 (the default) leaves the underlying yaml library's own default in
+// place.
+func WithIndent(n int) Option {
+	return optionFunc(func(e *Encoder) { e.indent = n })
+}
+
+// WithSortedKeys controls whether map keys are emitted sorted alphabetically
+// (the default) or in their original insertion order, taken from each
+// value's meta.Object.Origins line/column.
+func WithSortedKeys(sorted bool) Option {
+	return optionFunc(func(e *Encoder) { e.unsorted = !sorted })
+}
+
+// WithCommentFormat overrides how a single meta.Origin is rendered into an
+// origin comment.  When a value has multiple origins the rendered strings
+// are joined with ", ".
+func WithCommentFormat(format func(meta.Origin) string) Option {
+	return optionFunc(func(e *Encoder) { e.commentFormat = format })
+}
+
+// WithCommentColumn overrides the automatic column alignment normally
+// computed by alignComments, aligning every origin comment to a fixed
+// column instead.  A line whose content already reaches that column keeps
+// its comment right after the content.
+func WithCommentColumn(col int) Option {
+	return optionFunc(func(e *Encoder) { e.commentColumn = col })
+}
+
+// WithOriginFilter selects which of a value's origins are rendered in its
+// comment.  This is useful, for example, to show only the "winning" origin
+// of a value that was merged from multiple configuration layers.
+func WithOriginFilter(filter func([]meta.Origin) []meta.Origin) Option {
+	return optionFunc(func(e *Encoder) { e.originFilter = filter })
+}
 
 // Extensions returns the supported extensions.
 func (e Encoder) Extensions() []string {
@@ -65,7 +128,7 @@ func (e Encoder) Extensions() []string {
 
 // Encode encodes the value provided into yaml and returns the bytes.
 func (e Encoder) Encode(a any) ([]byte, error) {
-	return yml.Marshal(a)
+	return e.marshal(a)
 }
 
 // Encode encodes the meta.Object provided into yaml with comments showing the
@@ -80,63 +143,104 @@ func (e Encoder) EncodeExtended(obj meta.Object) ([]byte, error) {
 		Tag:  "!!map",
 	}
 
-	n, err := encode(obj)
+	n, err := e.encode(obj)
 	if err != nil {
 		return nil, err
 	}
 	doc.Content = append(doc.Content, &n)
 
-	b, err := yml.Marshal(&doc)
+	b, err := e.marshal(&doc)
 	if err != nil {
 		return nil, err
 	}
 
-	return alignComments(b)
+	return e.alignComments(b)
 }
 
-// determineStyle determines the best YAML style (|- or quoted) for a given string.
+// marshal encodes v into yaml, honoring the indent configured via
+// WithIndent() if any.
+func (e Encoder) marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := yml.NewEncoder(&buf)
+	if e.indent > 0 {
+		enc.SetIndent(e.indent)
+	}
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// determineStyle determines the best YAML style (|-, "" or plain) for a
+// given string.
 func determineStyle(input string) yml.Style {
-	// Check flags to decide whether we need to quote the string
+	if len(input) == 0 {
+		return yml.DoubleQuotedStyle
+	}
+
+	// Check flags to decide whether we need to quote the string, and
+	// whether a block scalar is even viable for it.
 	needsQuotes := false
 	containsNewlines := false
+	blockIncompatible := false
 
 	for idx, ch := range input {
 		switch {
 		case ch == '\n':
-			// Newlines are fine in a block scalar
+			// Newlines are fine in a block scalar.
 			containsNewlines = true
-		case ch < 0x20 && ch != '\t': // Non-printable ASCII except tab
+		case ch < 0x20 && ch != '\t':
+			// Non-printable ASCII (other than tab) can't be represented in
+			// a block scalar at all, so it rules that style out entirely.
 			needsQuotes = true
+			blockIncompatible = true
 		case (ch == ':' || ch == '-') && idx == 0:
-			// Leading `:` or `-` must be quoted
+			// Leading `:` or `-` must be quoted.
 			needsQuotes = true
+		case ch == '#' && idx+1 < len(input) && input[idx+1] == ' ':
+			// A `# ` on a block scalar line would be taken for the origin
+			// comment by alignComments(), so rule that style out.
+			blockIncompatible = true
 		case ch == '\\':
-			// Backslash must be quoted to preserve literal value
+			// Backslash must be quoted to preserve literal value.
 			needsQuotes = true
 		case ch == '"':
-			// Double quotes must be escaped if quoted
+			// Double quotes must be escaped if quoted.
 			needsQuotes = true
 		case ch > 0x7F:
-			// Unicode characters above ASCII 127
+			// Unicode characters above ASCII 127.
 			needsQuotes = true
 		}
 	}
 
-	// If the string contains newlines and doesn't need quotes, use |-
-	if containsNewlines && !needsQuotes {
-		// return yml.LiteralStyle <-- This is ideal, but there is a bug
-		// in the yaml encoder that causes it to encode the output wrong that
-		// I can't figure out how to work around.  So we'll use the next best
-		// thing.
-		return yml.DoubleQuotedStyle
+	hasLeadingSpace := input[0] == ' ' || input[0] == '\t'
+	hasTrailingSpace := unicode.IsSpace(rune(input[len(input)-1]))
+
+	// A leading or trailing space requires an explicit indentation/chomping
+	// indicator to be unambiguous, which we don't emit, so fall back to a
+	// quoted style for those cases.  (This also sidesteps the folded style
+	// entirely: it folds single interior newlines into spaces, which would
+	// silently change a value like "a\nb" on decode.)  yaml.v3's emitter
+	// agrees: it refuses block style for any scalar with leading or
+	// trailing whitespace and falls back to double-quoted on its own, so
+	// returning anything else here wouldn't match what's actually emitted.
+	if containsNewlines && !blockIncompatible && !hasLeadingSpace && !hasTrailingSpace {
+		return yml.LiteralStyle
 	}
 
-	// If the string needs quotes or is empty or ends with a space, use ""
-	if needsQuotes || len(input) == 0 || unicode.IsSpace(rune(input[len(input)-1])) {
+	// If the string needs quotes, contains newlines we can't put in a block
+	// scalar, or ends with a space, use "".
+	if needsQuotes || hasTrailingSpace || containsNewlines {
 		return yml.DoubleQuotedStyle
 	}
 
-	// Default to plain style
+	// Default to plain style.
 	return yml.TaggedStyle
 }
 
@@ -149,11 +253,17 @@ func encoderWrapper(n *yml.Node, v any) (err error) {
 		}
 	}()
 
-	// This is to work around a bug in the yaml encoder where encodes the output
-	// wrong if the string contains a newline.
+	// This is to work around a bug in the yaml encoder where it encodes the
+	// output wrong if the string contains a newline.  Setting the tag
+	// explicitly keeps the emitter from second-guessing the chosen style
+	// based on its own implicit typing of the value.  The TaggedStyle bit is
+	// cleared so the tag itself is only printed (as quoting) where a plain
+	// value would otherwise not decode as a string.
 	if s, ok := v.(string); ok {
 		n.Style = determineStyle(s)
 		n.Kind = yml.ScalarNode
+		n.Tag = "!!str"
+		n.Style &^= yml.TaggedStyle
 		n.Value = s
 		return nil
 	}
@@ -161,12 +271,80 @@ func encoderWrapper(n *yml.Node, v any) (err error) {
 	return n.Encode(v)
 }
 
+// defaultCommentFormat renders a single meta.Origin the same way
+// meta.Object.OriginString() does: `file:line[col]`.
+func defaultCommentFormat(o meta.Origin) string {
+	return fmt.Sprintf("%s:%d[%d]", o.File, o.Line, o.Col)
+}
+
+// renderComment builds the origin comment for obj, honoring WithOriginFilter
+// and WithCommentFormat if configured.  With neither configured this matches
+// obj.OriginString() exactly, preserving the default behavior.
+func (e Encoder) renderComment(obj meta.Object) string {
+	if e.originFilter == nil && e.commentFormat == nil {
+		return obj.OriginString()
+	}
+
+	origins := obj.Origins
+	if e.originFilter != nil {
+		origins = e.originFilter(origins)
+	}
+	if len(origins) == 0 {
+		return ""
+	}
+
+	format := e.commentFormat
+	if format == nil {
+		format = defaultCommentFormat
+	}
+
+	parts := make([]string, 0, len(origins))
+	for _, o := range origins {
+		parts = append(parts, format(o))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// sortedKeys returns obj's map keys, either sorted alphabetically (the
+// default) or in their original insertion order, per WithSortedKeys().
+func (e Encoder) sortedKeys(obj meta.Object) []string {
+	keys := make([]string, 0, len(obj.Map))
+	for key := range obj.Map {
+		keys = append(keys, key)
+	}
+
+	if e.unsorted {
+		sort.Slice(keys, func(i, j int) bool {
+			oi := firstOrigin(obj.Map[keys[i]])
+			oj := firstOrigin(obj.Map[keys[j]])
+			if oi.Line != oj.Line {
+				return oi.Line < oj.Line
+			}
+			return oi.Col < oj.Col
+		})
+		return keys
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// firstOrigin returns obj's first recorded origin, or the zero value if it
+// has none.
+func firstOrigin(obj meta.Object) meta.Origin {
+	if len(obj.Origins) == 0 {
+		return meta.Origin{}
+	}
+	return obj.Origins[0]
+}
+
 // encode is an internal helper function that builds the yml.Node based tree
 // to give to the yaml encoder.  This is likely specific to this yaml encoder.
 // Also always be sure to include a comment on each line so the alignment process
 // in alignComments() is simpler logic.
-func encode(obj meta.Object) (n yml.Node, err error) {
-	n.LineComment = encodeComment(obj.OriginString())
+func (e Encoder) encode(obj meta.Object) (n yml.Node, err error) {
+	n.LineComment = encodeComment(e.renderComment(obj))
 	kind := obj.Kind()
 
 	if kind == meta.Value {
@@ -175,7 +353,7 @@ func encode(obj meta.Object) (n yml.Node, err error) {
 		if err != nil {
 			return yml.Node{}, err
 		}
-		n.LineComment = encodeComment(obj.OriginString()) // The encode wipes this out.
+		n.LineComment = encodeComment(e.renderComment(obj)) // The encode wipes this out.
 		return n, nil
 	}
 
@@ -183,7 +361,7 @@ func encode(obj meta.Object) (n yml.Node, err error) {
 		n.Kind = yml.SequenceNode
 
 		for _, v := range obj.Array {
-			sub, err := encode(v)
+			sub, err := e.encode(v)
 			if err != nil {
 				return yml.Node{}, err
 			}
@@ -195,21 +373,14 @@ func encode(obj meta.Object) (n yml.Node, err error) {
 
 	n.Kind = yml.MappingNode
 
-	// Sort the keys so the output order is predictable, making testing easier.
-	keys := make([]string, 0, len(obj.Map))
-	for key := range obj.Map {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-
-	for _, k := range keys {
+	for _, k := range e.sortedKeys(obj) {
 		v := obj.Map[k]
 		key := yml.Node{
 			Kind:        yml.ScalarNode,
-			LineComment: encodeComment(v.OriginString()),
+			LineComment: encodeComment(e.renderComment(v)),
 			Value:       k,
 		}
-		val, err := encode(v)
+		val, err := e.encode(v)
 		if err != nil {
 			return yml.Node{}, err
 		}
@@ -243,9 +414,11 @@ func decodeComment(s string) (string, error) {
 }
 
 // alignComments finds the longest line, adds 8 spaces, then aligns the comments
-// to the next tabstop (assuming tabwidth is 4).  This is also where the comments
-// are decoded from base32.
-func alignComments(buf []byte) ([]byte, error) {
+// to the next tabstop (assuming tabwidth is 4), unless WithCommentColumn() was
+// used to fix the column instead.  This is also where the comments are
+// decoded from base32.  Continuation lines of a literal/folded block scalar
+// carry no comment of their own and are passed through unchanged.
+func (e Encoder) alignComments(buf []byte) ([]byte, error) {
 	// Assume each line is about 24 bytes long as a starting buffer size.
 	// A smaller line size guess reduces the re-allocations needed later.
 	lines := make([]string, 0, len(buf)/24)
@@ -262,30 +435,39 @@ func alignComments(buf []byte) ([]byte, error) {
 		lines = append(lines, line)
 	}
 
-	widest += 8 + (widest % 4)
+	if e.commentColumn > 0 {
+		widest = e.commentColumn
+	} else {
+		widest += 8 + (4-widest%4)%4
+	}
 
 	var b strings.Builder
 	for _, line := range lines {
-		if found := strings.LastIndex(line, "# "); found > 0 {
-			left := line[:found]
-			right := line[found:]
-			comment, err := decodeComment(right[2:])
-			if err != nil {
-				// This  isn't really possible unless the encoder below
-				// changes.  This seems better than either a silent failure
-				// or a panic.
-				return nil, err
-			}
-
-			b.WriteString(left)
-			for found < widest {
-				b.WriteString(" ")
-				found++
-			}
-			b.WriteString("# ")
-			b.WriteString(comment)
+		found := strings.LastIndex(line, "# ")
+		if found < 0 {
+			b.WriteString(line)
 			b.WriteString("\n")
+			continue
+		}
+
+		left := line[:found]
+		right := line[found:]
+		comment, err := decodeComment(right[2:])
+		if err != nil {
+			// This  isn't really possible unless the encoder below
+			// changes.  This seems better than either a silent failure
+			// or a panic.
+			return nil, err
+		}
+
+		b.WriteString(left)
+		for found < widest {
+			b.WriteString(" ")
+			found++
 		}
+		b.WriteString("# ")
+		b.WriteString(comment)
+		b.WriteString("\n")
 	}
 
 	return []byte(b.String()), nil
@@ -4,6 +4,8 @@
 package yamlencoder
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -95,6 +97,10 @@ func TestEncodeExtended(t *testing.T) {
 												Origins: []meta.Origin{{File: "file.yml", Line: 11, Col: 15}},
 												Value:   "water\nballoons\"",
 											},
+											{
+												Origins: []meta.Origin{{File: "file.yml", Line: 13, Col: 15}},
+												Value:   "damp\nhere ",
+											},
 										},
 									},
 								},
@@ -121,21 +127,27 @@ other:
             - |-
               water
               balloons"
+            - "damp\nhere "
         red: balloons
     trending: now
 `,
-			expectedExtended: `candy: bar                                  # file.yml:1[8]
-cats:                                       # file.yml:2[1]
-    - madd                                  # file.yml:3[7]
-    - tabby                                 # file.yml:4[7]
-other:                                      # file.yml:5[1]
-    things:                                 # file.yml:6[5]
-        green:                              # file.yml:8[9]
-            - grass                         # unknown
-            - "ground\nout"                 # file.yml:10[15]
-            - "water\nballoons\""           # file.yml:11[15]
-        red: balloons                       # file.yml:7[14]
-    trending: now                           # file.yml:12[15]
+			expectedExtended: `candy: bar                          # file.yml:1[8]
+cats:                               # file.yml:2[1]
+    - madd                          # file.yml:3[7]
+    - tabby                         # file.yml:4[7]
+other:                              # file.yml:5[1]
+    things:                         # file.yml:6[5]
+        green:                      # file.yml:8[9]
+            - grass                 # unknown
+            - |-                    # file.yml:10[15]
+              ground
+              out
+            - |-                    # file.yml:11[15]
+              water
+              balloons"
+            - "damp\nhere "         # file.yml:13[15]
+        red: balloons               # file.yml:7[14]
+    trending: now                   # file.yml:12[15]
 `,
 		},
 		{
@@ -228,11 +240,24 @@ other:                                      # file.yml:5[1]
 					t.Logf("got:\n%s", string(got))
 				}
 
+				extended := got
 				raw := tc.in.ToRaw()
 
 				got, err = e.Encode(raw)
 				assert.NoError(err)
 				assert.Empty(cmp.Diff(tc.expected, string(got)), "Encode(raw) failed")
+
+				// Round-trip the EncodeExtended() output, not the plain
+				// Encode() output above, back through yaml.Unmarshal and
+				// confirm every value survives byte-for-byte.  The origin
+				// comments are just trailing YAML comments, so
+				// yaml.Unmarshal discards them and decodes the same value
+				// tree.  This is what catches regressions in the block
+				// scalar handling, since only EncodeExtended() exercises
+				// determineStyle().
+				var decoded any
+				assert.NoError(yaml.Unmarshal(extended, &decoded))
+				assert.Empty(cmp.Diff(raw, decoded), "round trip through yaml.Unmarshal changed a value")
 				return
 			}
 
@@ -250,13 +275,103 @@ func TestDecodeComment(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	obj := meta.Object{
+		Map: map[string]meta.Object{
+			"apple": {
+				Origins: []meta.Origin{{File: "a.yml", Line: 9, Col: 1}},
+				Value:   "second",
+			},
+			"zebra": {
+				Origins: []meta.Origin{{File: "a.yml", Line: 2, Col: 1}},
+				Value:   "first",
+			},
+		},
+	}
+
+	t.Run("default matches the zero value", func(t *testing.T) {
+		var zero Encoder
+		got, err := zero.EncodeExtended(obj)
+		assert.NoError(err)
+
+		e := New()
+		got2, err := e.EncodeExtended(obj)
+		assert.NoError(err)
+		assert.Equal(string(got), string(got2))
+	})
+
+	t.Run("WithSortedKeys(false) uses origin order instead of alphabetical", func(t *testing.T) {
+		e := New(WithSortedKeys(false))
+		got, err := e.EncodeExtended(obj)
+		assert.NoError(err)
+
+		zebraIdx := strings.Index(string(got), "zebra:")
+		appleIdx := strings.Index(string(got), "apple:")
+		assert.True(zebraIdx >= 0 && appleIdx >= 0 && zebraIdx < appleIdx)
+	})
+
+	t.Run("WithCommentFormat overrides origin rendering", func(t *testing.T) {
+		e := New(WithCommentFormat(func(o meta.Origin) string {
+			return o.File + "@" + strconv.Itoa(o.Line)
+		}))
+		got, err := e.EncodeExtended(obj)
+		assert.NoError(err)
+		assert.Contains(string(got), "# a.yml@9")
+		assert.Contains(string(got), "# a.yml@2")
+	})
+
+	t.Run("WithOriginFilter selects which origins are rendered", func(t *testing.T) {
+		merged := meta.Object{
+			Map: map[string]meta.Object{
+				"candy": {
+					Origins: []meta.Origin{
+						{File: "base.yml", Line: 1, Col: 8},
+						{File: "override.yml", Line: 3, Col: 8},
+					},
+					Value: "bar",
+				},
+			},
+		}
+
+		e := New(WithOriginFilter(func(origins []meta.Origin) []meta.Origin {
+			return origins[len(origins)-1:]
+		}))
+		got, err := e.EncodeExtended(merged)
+		assert.NoError(err)
+		assert.Contains(string(got), "# override.yml:3[8]")
+		assert.NotContains(string(got), "base.yml")
+	})
+
+	t.Run("WithCommentColumn fixes the alignment column", func(t *testing.T) {
+		e := New(WithCommentColumn(20))
+		got, err := e.EncodeExtended(obj)
+		assert.NoError(err)
+
+		for _, line := range strings.Split(strings.TrimRight(string(got), "\n"), "\n") {
+			idx := strings.Index(line, "# ")
+			assert.Equal(20, idx)
+		}
+	})
+
+	t.Run("WithIndent changes the indentation of plain output", func(t *testing.T) {
+		e := New(WithIndent(2))
+		got, err := e.Encode(map[string]any{
+			"cats": []any{"madd", "tabby"},
+		})
+		assert.NoError(err)
+		assert.Contains(string(got), "\n  - madd\n")
+	})
+}
+
 func TestDetermineStyle(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected yaml.Style
 	}{
 		{"simple", yaml.TaggedStyle},
-		{"multi\nline", yaml.DoubleQuotedStyle},
+		{"multi\nline", yaml.LiteralStyle},
 		{"noleadingColon:", yaml.TaggedStyle},
 		{":leadingColon", yaml.DoubleQuotedStyle},
 		{"-leadingDash", yaml.DoubleQuotedStyle},
@@ -267,6 +382,10 @@ func TestDetermineStyle(t *testing.T) {
 		{"unicode\u0080", yaml.DoubleQuotedStyle},
 		{"", yaml.DoubleQuotedStyle},
 		{"endsWithSpace ", yaml.DoubleQuotedStyle},
+		{"trailing space\nline ", yaml.DoubleQuotedStyle},
+		{" leading space\nline", yaml.DoubleQuotedStyle},
+		{"control\x01\nchar", yaml.DoubleQuotedStyle},
+		{"quote\"\ninside", yaml.LiteralStyle},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2022 Weston Schmidt <weston_schmidt@alumni.purdue.edu>
+// SPDX-License-Identifier: Apache-2.0
+
+// jsonencoder provides a way to encode both the simple form and the detailed
+// form of configuration data for the goschtalt library as JSON.
+//
+// # Detailed Output
+//
+// Since the standard encoding/json package doesn't preserve key order or
+// comments, the detailed form is produced by walking the meta.Object tree
+// directly and rendering a JSON5/JSONC style document.  The origin of each
+// value is included as a `file:line[col]` trailing comment when goschtalt
+// knows the origin.  Not all decoders support tracking all this information.
+// The comment will always be present so it's easier to handle the file using
+// simple cli text processors.
+//
+// Example
+//
+//	{                           // file.yml:1[1]
+//	    "candy": "bar",         // file.yml:1[8]
+//	    "cats": [               // file.yml:2[1]
+//	        "madd",             // file.yml:3[7]
+//	        "tabby"             // file.yml:4[7]
+//	    ]
+//	}
+package jsonencoder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/goschtalt/goschtalt"
+	"github.com/goschtalt/goschtalt/pkg/encoder"
+	"github.com/goschtalt/goschtalt/pkg/meta"
+)
+
+var (
+	ErrEncoding = errors.New("encoding error")
+)
+
+// Ensure interface compliance.
+var _ encoder.Encoder = (*Encoder)(nil)
+
+// Use init to automatically wire this encoder as one available for goschtalt
+// simply by including this package.
+func init() {
+	var e Encoder
+	goschtalt.DefaultOptions = append(goschtalt.DefaultOptions, goschtalt.WithEncoder(e))
+}
+
+// Encoder is a class for the json encoder.
+type Encoder struct{}
+
+// Extensions returns the supported extensions.
+func (e Encoder) Extensions() []string {
+	return []string{"json"}
+}
+
+// Encode encodes the value provided into json and returns the bytes.
+func (e Encoder) Encode(a any) ([]byte, error) {
+	b, err := json.MarshalIndent(a, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// EncodeExtended encodes the meta.Object provided into JSON with comments
+// showing the origin of the configuration and returns the bytes.
+func (e Encoder) EncodeExtended(obj meta.Object) ([]byte, error) {
+	if len(obj.Map) == 0 {
+		return []byte("null\n"), nil
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, "", obj, 0, false); err != nil {
+		return nil, err
+	}
+
+	return alignComments(buf.Bytes())
+}
+
+// encode is an internal helper function that walks the meta.Object tree and
+// writes the resulting JSONC document, one line at a time, into buf.  This is
+// likely specific to this json encoder.  Also always be sure to include a
+// comment on each value line so the alignment process in alignComments()
+// is simpler logic.
+func encode(buf *bytes.Buffer, key string, obj meta.Object, indent int, comma bool) error {
+	pad := strings.Repeat("    ", indent)
+
+	prefix := pad
+	if key != "" {
+		k, err := json.Marshal(key)
+		if err != nil {
+			return ErrEncoding
+		}
+		prefix += string(k) + ": "
+	}
+
+	switch obj.Kind() {
+	case meta.Array:
+		return encodeArray(buf, prefix, obj, indent, comma)
+	case meta.Map:
+		return encodeMap(buf, prefix, obj, indent, comma)
+	default:
+		return encodeScalar(buf, prefix, obj, comma)
+	}
+}
+
+// encodeScalar writes a single value line, including its trailing origin
+// comment.
+func encodeScalar(buf *bytes.Buffer, prefix string, obj meta.Object, comma bool) error {
+	v, err := json.Marshal(obj.Value)
+	if err != nil {
+		return ErrEncoding
+	}
+
+	line := prefix + string(v)
+	if comma {
+		line += ","
+	}
+
+	writeLine(buf, line, obj.OriginString())
+	return nil
+}
+
+// encodeArray writes the opening `[`, the recursively encoded elements, and
+// the closing `]` for an array node.
+func encodeArray(buf *bytes.Buffer, prefix string, obj meta.Object, indent int, comma bool) error {
+	if len(obj.Array) == 0 {
+		line := prefix + "[]"
+		if comma {
+			line += ","
+		}
+		writeLine(buf, line, obj.OriginString())
+		return nil
+	}
+
+	writeLine(buf, prefix+"[", obj.OriginString())
+
+	for i, v := range obj.Array {
+		if err := encode(buf, "", v, indent+1, i < len(obj.Array)-1); err != nil {
+			return err
+		}
+	}
+
+	closing := strings.Repeat("    ", indent) + "]"
+	if comma {
+		closing += ","
+	}
+	buf.WriteString(closing + "\n")
+
+	return nil
+}
+
+// encodeMap writes the opening `{`, the recursively encoded, key sorted
+// members, and the closing `}` for a map node.
+func encodeMap(buf *bytes.Buffer, prefix string, obj meta.Object, indent int, comma bool) error {
+	if len(obj.Map) == 0 {
+		line := prefix + "{}"
+		if comma {
+			line += ","
+		}
+		writeLine(buf, line, obj.OriginString())
+		return nil
+	}
+
+	writeLine(buf, prefix+"{", obj.OriginString())
+
+	// Sort the keys so the output order is predictable, making testing easier.
+	keys := make([]string, 0, len(obj.Map))
+	for key := range obj.Map {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		if err := encode(buf, k, obj.Map[k], indent+1, i < len(keys)-1); err != nil {
+			return err
+		}
+	}
+
+	closing := strings.Repeat("    ", indent) + "}"
+	if comma {
+		closing += ","
+	}
+	buf.WriteString(closing + "\n")
+
+	return nil
+}
+
+// writeLine appends content followed by an encoded, trailing origin comment.
+func writeLine(buf *bytes.Buffer, content, origin string) {
+	buf.WriteString(content)
+	buf.WriteString(" // ")
+	buf.WriteString(encodeComment(origin))
+	buf.WriteString("\n")
+}
+
+// encodeComment base32 encodes the comment so the processing needed to align
+// the comments is easier.  We can simply look for the right-most // because
+// of the encoding excluding // from the character set.
+func encodeComment(s string) string {
+	if len(s) == 0 {
+		s = "unknown"
+	}
+	return base32.StdEncoding.EncodeToString([]byte(s))
+}
+
+// decodeComment is the reverse of encodeComment(), but handles the case of if
+// decoding fails.  It should never fail, but it checks for it anyway.
+func decodeComment(s string) (string, error) {
+	buf, err := base32.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// alignComments finds the longest line, adds 8 spaces, then aligns the
+// comments to the next tabstop (assuming tabwidth is 4).  This is also where
+// the comments are decoded from base32.  Unlike the yaml encoder, closing
+// `]`/`}` lines carry no comment and are passed through unchanged.
+func alignComments(buf []byte) ([]byte, error) {
+	// Assume each line is about 24 bytes long as a starting buffer size.
+	// A smaller line size guess reduces the re-allocations needed later.
+	lines := make([]string, 0, len(buf)/24)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+
+	var widest int
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if found := strings.LastIndex(line, "// "); found > widest {
+			widest = found
+		}
+
+		lines = append(lines, line)
+	}
+
+	widest += 8 + (4-widest%4)%4
+
+	var b strings.Builder
+	for _, line := range lines {
+		found := strings.LastIndex(line, "// ")
+		if found < 0 {
+			b.WriteString(line)
+			b.WriteString("\n")
+			continue
+		}
+
+		left := line[:found]
+		right := line[found:]
+		comment, err := decodeComment(right[3:])
+		if err != nil {
+			// This isn't really possible unless the encoder above
+			// changes.  This seems better than either a silent failure
+			// or a panic.
+			return nil, err
+		}
+
+		b.WriteString(left)
+		for found < widest {
+			b.WriteString(" ")
+			found++
+		}
+		b.WriteString("// ")
+		b.WriteString(comment)
+		b.WriteString("\n")
+	}
+
+	return []byte(b.String()), nil
+}
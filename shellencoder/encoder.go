@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2022 Weston Schmidt <weston_schmidt@alumni.purdue.edu>
+// SPDX-License-Identifier: Apache-2.0
+
+// shellencoder provides a way to encode configuration data for the goschtalt
+// library as POSIX shell variable assignments, suitable for `source`-ing.
+//
+// Nested map keys are joined with `_` and uppercased, array elements are
+// given a numeric suffix, and values are single-quoted with embedded single
+// quotes escaped.
+//
+// # Detailed Output
+//
+// The detailed form adds a `# file:line[col]` comment above each assignment
+// showing where the value originated, when goschtalt knows the origin.
+//
+// Example
+//
+//	CANDY='bar'
+//	CATS_0='madd'
+//	CATS_1='tabby'
+//	OTHER_THINGS_RED='balloons'
+package shellencoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goschtalt/goschtalt"
+	"github.com/goschtalt/goschtalt/pkg/encoder"
+	"github.com/goschtalt/goschtalt/pkg/meta"
+)
+
+var (
+	ErrEncoding = errors.New("encoding error")
+)
+
+// Ensure interface compliance.
+var _ encoder.Encoder = (*Encoder)(nil)
+
+// Use init to automatically wire this encoder as one available for goschtalt
+// simply by including this package.
+func init() {
+	var e Encoder
+	goschtalt.DefaultOptions = append(goschtalt.DefaultOptions, goschtalt.WithEncoder(e))
+}
+
+// Encoder is a class for the shell variables encoder.
+type Encoder struct{}
+
+// Extensions returns the supported extensions.
+func (e Encoder) Extensions() []string {
+	return []string{"env", "sh"}
+}
+
+// Encode encodes the value provided into shell variable assignments and
+// returns the bytes.
+func (e Encoder) Encode(a any) ([]byte, error) {
+	m, ok := a.(map[string]any)
+	if !ok {
+		return nil, ErrEncoding
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, "", m); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeExtended encodes the meta.Object provided into shell variable
+// assignments with comments showing the origin of the configuration and
+// returns the bytes.
+func (e Encoder) EncodeExtended(obj meta.Object) ([]byte, error) {
+	if len(obj.Map) == 0 {
+		return []byte{}, nil
+	}
+
+	var buf bytes.Buffer
+	if err := encodeExtended(&buf, "", obj); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encode is an internal helper function that flattens a raw value tree
+// (map[string]any/[]any, the shape returned by meta.Object.ToRaw()) into
+// shell assignments.
+func encode(buf *bytes.Buffer, prefix string, a any) error {
+	switch t := a.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for key := range t {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := encode(buf, joinKey(prefix, key), t[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		for i, v := range t {
+			if err := encode(buf, fmt.Sprintf("%s_%d", prefix, i), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		s, err := toShellValue(t)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteString(strings.ToUpper(prefix))
+		buf.WriteString("=")
+		buf.WriteString(quoteShell(s))
+		buf.WriteString("\n")
+		return nil
+	}
+}
+
+// encodeExtended is an internal helper function that walks the meta.Object
+// tree and writes the resulting shell assignments, with an origin comment
+// above each one, into buf.
+func encodeExtended(buf *bytes.Buffer, prefix string, obj meta.Object) error {
+	switch obj.Kind() {
+	case meta.Map:
+		keys := make([]string, 0, len(obj.Map))
+		for key := range obj.Map {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := encodeExtended(buf, joinKey(prefix, key), obj.Map[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case meta.Array:
+		for i, v := range obj.Array {
+			if err := encodeExtended(buf, fmt.Sprintf("%s_%d", prefix, i), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		s, err := toShellValue(obj.Value)
+		if err != nil {
+			return err
+		}
+
+		origin := obj.OriginString()
+		if len(origin) == 0 {
+			origin = "unknown"
+		}
+
+		buf.WriteString("# ")
+		buf.WriteString(origin)
+		buf.WriteString("\n")
+		buf.WriteString(strings.ToUpper(prefix))
+		buf.WriteString("=")
+		buf.WriteString(quoteShell(s))
+		buf.WriteString("\n")
+		return nil
+	}
+}
+
+// joinKey joins a flattened key prefix with the next path element using `_`,
+// leaving the case alone until the value is finally written out uppercased.
+func joinKey(prefix, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "_" + key
+}
+
+// toShellValue converts a scalar value into its shell textual representation.
+// Types that can't reasonably be represented (channels, funcs, etc.) result
+// in ErrEncoding.
+func toShellValue(v any) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	case bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return fmt.Sprintf("%v", t), nil
+	default:
+		return "", ErrEncoding
+	}
+}
+
+// quoteShell single-quotes s, escaping any embedded single quotes the way a
+// POSIX shell requires (`'\''`).
+func quoteShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
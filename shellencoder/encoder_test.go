@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2022 Weston Schmidt <weston_schmidt@alumni.purdue.edu>
+// SPDX-License-Identifier: Apache-2.0
+
+package shellencoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/goschtalt/goschtalt/pkg/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensions(t *testing.T) {
+	assert := assert.New(t)
+
+	var e Encoder
+	got := e.Extensions()
+
+	assert.Empty(cmp.Diff([]string{"env", "sh"}, got))
+}
+
+func TestEncodeExtended(t *testing.T) {
+	tests := []struct {
+		description      string
+		in               meta.Object
+		expected         string
+		expectedExtended string
+		expectedErr      error
+	}{
+		{
+			description:      "A test of empty.",
+			in:               meta.Object{},
+			expected:         "",
+			expectedExtended: "",
+		},
+		{
+			description: "A simple test.",
+			// Input vector in yaml:
+			//candy: bar
+			//cats:
+			//    - madd
+			//    - tabby
+			//other:
+			//    things:
+			//        red: it's grand
+			//        green:
+			//            - grass
+			//            - "multi\nline"
+			//    trending: now
+			in: meta.Object{
+				Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 1}},
+				Map: map[string]meta.Object{
+					"candy": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 8}},
+						Value:   "bar",
+					},
+					"cats": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 2, Col: 1}},
+						Array: []meta.Object{
+							{
+								Origins: []meta.Origin{{File: "file.yml", Line: 3, Col: 7}},
+								Value:   "madd",
+							},
+							{
+								Origins: []meta.Origin{{File: "file.yml", Line: 4, Col: 7}},
+								Value:   "tabby",
+							},
+						},
+					},
+					"other": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 5, Col: 1}},
+						Map: map[string]meta.Object{
+							"things": {
+								Origins: []meta.Origin{{File: "file.yml", Line: 6, Col: 5}},
+								Map: map[string]meta.Object{
+									"red": {
+										Origins: []meta.Origin{{File: "file.yml", Line: 7, Col: 14}},
+										Value:   "it's grand",
+									},
+									"green": {
+										Origins: []meta.Origin{{File: "file.yml", Line: 8, Col: 9}},
+										Array: []meta.Object{
+											{
+												// Leave the origin off here to show what happens if none
+												// is present.
+												Value: "grass",
+											},
+											{
+												Origins: []meta.Origin{{File: "file.yml", Line: 9, Col: 15}},
+												Value:   "multi\nline",
+											},
+										},
+									},
+								},
+							},
+							"trending": {
+								Origins: []meta.Origin{{File: "file.yml", Line: 12, Col: 15}},
+								Value:   "now",
+							},
+						},
+					},
+				},
+			},
+			expected: "CANDY='bar'\n" +
+				"CATS_0='madd'\n" +
+				"CATS_1='tabby'\n" +
+				"OTHER_THINGS_GREEN_0='grass'\n" +
+				"OTHER_THINGS_GREEN_1='multi\nline'\n" +
+				"OTHER_THINGS_RED='it'\\''s grand'\n" +
+				"OTHER_TRENDING='now'\n",
+			expectedExtended: "# file.yml:1[8]\n" +
+				"CANDY='bar'\n" +
+				"# file.yml:3[7]\n" +
+				"CATS_0='madd'\n" +
+				"# file.yml:4[7]\n" +
+				"CATS_1='tabby'\n" +
+				"# unknown\n" +
+				"OTHER_THINGS_GREEN_0='grass'\n" +
+				"# file.yml:9[15]\n" +
+				"OTHER_THINGS_GREEN_1='multi\nline'\n" +
+				"# file.yml:7[14]\n" +
+				"OTHER_THINGS_RED='it'\\''s grand'\n" +
+				"# file.yml:12[15]\n" +
+				"OTHER_TRENDING='now'\n",
+		},
+		{
+			description: "try to encode a channel (invalid) for verifying the failure path",
+			in: meta.Object{
+				Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 1}},
+				Map: map[string]meta.Object{
+					"candy": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 8}},
+						Value:   make(chan int),
+					},
+				},
+			},
+			expectedErr: ErrEncoding,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var e Encoder
+			got, err := e.EncodeExtended(tc.in)
+
+			if tc.expectedErr == nil {
+				assert.NoError(err)
+				assert.Empty(cmp.Diff(tc.expectedExtended, string(got)), "EncodeExtended(obj) failed")
+
+				if len(tc.in.Map) == 0 {
+					return
+				}
+
+				raw := tc.in.ToRaw()
+
+				got, err = e.Encode(raw)
+				assert.NoError(err)
+				assert.Empty(cmp.Diff(tc.expected, string(got)), "Encode(raw) failed")
+				return
+			}
+
+			assert.ErrorIs(err, tc.expectedErr)
+			assert.Nil(got)
+		})
+	}
+}
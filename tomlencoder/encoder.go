@@ -0,0 +1,407 @@
+// SPDX-FileCopyrightText: 2022 Weston Schmidt <weston_schmidt@alumni.purdue.edu>
+// SPDX-License-Identifier: Apache-2.0
+
+// tomlencoder provides a way to encode both the simple form and the detailed
+// form of configuration data for the goschtalt library as TOML.
+//
+// # Detailed Output
+//
+// The details about where the configuration value originated are included as
+// a `file:line[col]` comment on each key-value line and table header when
+// goschtalt knows the origin.  Not all decoders support tracking all this
+// information.  The comment will always be present so it's easier to handle
+// the file using simple cli text processors.
+//
+// Example
+//
+//	candy = "bar"                   # file.yml:1[8]
+//	cats = ["madd", "tabby"]        # file.yml:2[1]
+//
+//	[other]                         # file.yml:5[1]
+//	trending = "now"                # file.yml:12[15]
+//
+//	[other.things]                  # file.yml:6[5]
+//	red = "balloons"                # file.yml:7[14]
+package tomlencoder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goschtalt/goschtalt"
+	"github.com/goschtalt/goschtalt/pkg/encoder"
+	"github.com/goschtalt/goschtalt/pkg/meta"
+)
+
+var (
+	ErrEncoding = errors.New("encoding error")
+)
+
+// Ensure interface compliance.
+var _ encoder.Encoder = (*Encoder)(nil)
+
+// Use init to automatically wire this encoder as one available for goschtalt
+// simply by including this package.
+func init() {
+	var e Encoder
+	goschtalt.DefaultOptions = append(goschtalt.DefaultOptions, goschtalt.WithEncoder(e))
+}
+
+// Encoder is a class for the toml encoder.
+type Encoder struct{}
+
+// Extensions returns the supported extensions.
+func (e Encoder) Extensions() []string {
+	return []string{"toml"}
+}
+
+// Encode encodes the value provided into toml and returns the bytes.
+func (e Encoder) Encode(a any) ([]byte, error) {
+	m, ok := a.(map[string]any)
+	if !ok {
+		return nil, ErrEncoding
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, nil, m); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncodeExtended encodes the meta.Object provided into toml with comments
+// showing the origin of the configuration and returns the bytes.
+func (e Encoder) EncodeExtended(obj meta.Object) ([]byte, error) {
+	if len(obj.Map) == 0 {
+		return []byte{}, nil
+	}
+
+	var buf bytes.Buffer
+	if err := encodeExtended(&buf, nil, obj); err != nil {
+		return nil, err
+	}
+
+	return alignComments(buf.Bytes())
+}
+
+// encode is an internal helper function that walks a raw value tree
+// (map[string]any/[]any, the shape returned by meta.Object.ToRaw()) and
+// writes the resulting TOML document into buf.
+func encode(buf *bytes.Buffer, path []string, m map[string]any) error {
+	scalars, tables, arrayTables := classify(m)
+
+	for _, k := range scalars {
+		lit, err := valueLiteral(m[k])
+		if err != nil {
+			return err
+		}
+		buf.WriteString(k + " = " + lit + "\n")
+	}
+
+	for _, k := range tables {
+		newPath := append(append([]string{}, path...), k)
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("[" + strings.Join(newPath, ".") + "]\n")
+		if err := encode(buf, newPath, m[k].(map[string]any)); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range arrayTables {
+		newPath := append(append([]string{}, path...), k)
+		for _, item := range m[k].([]any) {
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString("[[" + strings.Join(newPath, ".") + "]]\n")
+			if err := encode(buf, newPath, item.(map[string]any)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodeExtended is the meta.Object flavor of encode, writing a trailing
+// origin comment on every key-value line and table header.
+func encodeExtended(buf *bytes.Buffer, path []string, obj meta.Object) error {
+	scalars, tables, arrayTables := classifyMeta(obj.Map)
+
+	for _, k := range scalars {
+		v := obj.Map[k]
+		lit, err := metaValueLiteral(v)
+		if err != nil {
+			return err
+		}
+		writeLine(buf, k+" = "+lit, v.OriginString())
+	}
+
+	for _, k := range tables {
+		v := obj.Map[k]
+		newPath := append(append([]string{}, path...), k)
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		writeLine(buf, "["+strings.Join(newPath, ".")+"]", v.OriginString())
+		if err := encodeExtended(buf, newPath, v); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range arrayTables {
+		v := obj.Map[k]
+		newPath := append(append([]string{}, path...), k)
+		for _, item := range v.Array {
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+			writeLine(buf, "[["+strings.Join(newPath, ".")+"]]", item.OriginString())
+			if err := encodeExtended(buf, newPath, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// classify splits a raw map's keys, sorted, into plain key-value keys,
+// sub-table keys, and array-of-tables keys.
+func classify(m map[string]any) (scalars, tables, arrayTables []string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch t := m[k].(type) {
+		case map[string]any:
+			tables = append(tables, k)
+		case []any:
+			if len(t) > 0 {
+				if _, ok := t[0].(map[string]any); ok {
+					arrayTables = append(arrayTables, k)
+					continue
+				}
+			}
+			scalars = append(scalars, k)
+		default:
+			scalars = append(scalars, k)
+		}
+	}
+
+	return scalars, tables, arrayTables
+}
+
+// classifyMeta is the meta.Object flavor of classify.
+func classifyMeta(m map[string]meta.Object) (scalars, tables, arrayTables []string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := m[k]
+		switch v.Kind() {
+		case meta.Map:
+			tables = append(tables, k)
+		case meta.Array:
+			if len(v.Array) > 0 && v.Array[0].Kind() == meta.Map {
+				arrayTables = append(arrayTables, k)
+				continue
+			}
+			scalars = append(scalars, k)
+		default:
+			scalars = append(scalars, k)
+		}
+	}
+
+	return scalars, tables, arrayTables
+}
+
+// valueLiteral renders a scalar or an inline array of scalars as TOML.
+func valueLiteral(v any) (string, error) {
+	if arr, ok := v.([]any); ok {
+		parts := make([]string, 0, len(arr))
+		for _, item := range arr {
+			s, err := valueLiteral(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+
+	return tomlValue(v)
+}
+
+// metaValueLiteral is the meta.Object flavor of valueLiteral.
+func metaValueLiteral(v meta.Object) (string, error) {
+	if v.Kind() == meta.Array {
+		parts := make([]string, 0, len(v.Array))
+		for _, item := range v.Array {
+			s, err := metaValueLiteral(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+
+	return tomlValue(v.Value)
+}
+
+// tomlValue renders a single scalar value as a TOML literal.  Types that
+// can't reasonably be represented (channels, funcs, etc.) result in
+// ErrEncoding.
+func tomlValue(v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return quoteTOMLString(t), nil
+	case bool:
+		return fmt.Sprintf("%v", t), nil
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return fmt.Sprintf("%v", t), nil
+	default:
+		return "", ErrEncoding
+	}
+}
+
+// quoteTOMLString quotes and escapes s as a TOML basic string, switching to
+// a multi-line basic string (triple quoted) when s contains a newline so the
+// value survives round-tripping literally.
+func quoteTOMLString(s string) string {
+	if strings.Contains(s, "\n") {
+		body := strings.ReplaceAll(s, `\`, `\\`)
+		body = strings.ReplaceAll(body, `"""`, `\"\"\"`)
+		body = strings.ReplaceAll(body, "\x00", `\u0000`)
+		return `"""` + "\n" + body + `"""`
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\x00':
+			b.WriteString(`\u0000`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// commentMarker precedes every origin comment appended by writeLine.  The
+// leading NUL can't occur in a value we emit, so alignComments can find the
+// real comment even when a multi-line string's own content happens to
+// contain the literal "# " sequence on one of its interior lines.
+const commentMarker = "\x00# "
+
+// writeLine appends content followed by an encoded, trailing origin comment.
+func writeLine(buf *bytes.Buffer, content, origin string) {
+	buf.WriteString(content)
+	buf.WriteString(commentMarker)
+	buf.WriteString(encodeComment(origin))
+	buf.WriteString("\n")
+}
+
+// encodeComment base32 encodes the comment so the processing needed to align
+// the comments is easier.  We can simply look for the right-most commentMarker
+// because of the encoding excluding # from the character set.
+func encodeComment(s string) string {
+	if len(s) == 0 {
+		s = "unknown"
+	}
+	return base32.StdEncoding.EncodeToString([]byte(s))
+}
+
+// decodeComment is the reverse of encodeComment(), but handles the case of if
+// decoding fails.  It should never fail, but it checks for it anyway.
+func decodeComment(s string) (string, error) {
+	buf, err := base32.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// alignComments finds the longest line, adds 8 spaces, then aligns the
+// comments to the next tabstop (assuming tabwidth is 4).  This is also where
+// the comments are decoded from base32.  Lines without a commentMarker
+// (blank separator lines, and interior lines of a multi-line string) are
+// passed through unchanged.
+func alignComments(buf []byte) ([]byte, error) {
+	// Assume each line is about 24 bytes long as a starting buffer size.
+	// A smaller line size guess reduces the re-allocations needed later.
+	lines := make([]string, 0, len(buf)/24)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+
+	var widest int
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if found := strings.LastIndex(line, commentMarker); found > widest {
+			widest = found
+		}
+
+		lines = append(lines, line)
+	}
+
+	widest += 8 + (4-widest%4)%4
+
+	var b strings.Builder
+	for _, line := range lines {
+		found := strings.LastIndex(line, commentMarker)
+		if found < 0 {
+			b.WriteString(line)
+			b.WriteString("\n")
+			continue
+		}
+
+		left := line[:found]
+		comment, err := decodeComment(line[found+len(commentMarker):])
+		if err != nil {
+			// This isn't really possible unless the encoder above
+			// changes.  This seems better than either a silent failure
+			// or a panic.
+			return nil, err
+		}
+
+		b.WriteString(left)
+		for found < widest {
+			b.WriteString(" ")
+			found++
+		}
+		b.WriteString("# ")
+		b.WriteString(comment)
+		b.WriteString("\n")
+	}
+
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,227 @@
+// SPDX-FileCopyrightText: 2022 Weston Schmidt <weston_schmidt@alumni.purdue.edu>
+// SPDX-License-Identifier: Apache-2.0
+
+package tomlencoder
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/goschtalt/goschtalt/pkg/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensions(t *testing.T) {
+	assert := assert.New(t)
+
+	var e Encoder
+	got := e.Extensions()
+
+	assert.Empty(cmp.Diff([]string{"toml"}, got))
+}
+
+func TestEncodeExtended(t *testing.T) {
+	tests := []struct {
+		description      string
+		in               meta.Object
+		expected         string
+		expectedExtended string
+		expectedErr      error
+	}{
+		{
+			description:      "A test of empty.",
+			in:               meta.Object{},
+			expected:         "",
+			expectedExtended: "",
+		},
+		{
+			description: "A simple test with a table and an array of tables.",
+			// Input vector in yaml:
+			//candy: bar
+			//cats:
+			//    - madd
+			//    - tabby
+			//other:
+			//    things:
+			//        red: balloons
+			//        green:
+			//            - grass
+			//            - ground
+			//    trending: now
+			//servers:
+			//    - name: alpha
+			//    - name: beta
+			in: meta.Object{
+				Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 1}},
+				Map: map[string]meta.Object{
+					"candy": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 8}},
+						Value:   "bar",
+					},
+					"cats": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 2, Col: 1}},
+						Array: []meta.Object{
+							{
+								Origins: []meta.Origin{{File: "file.yml", Line: 3, Col: 7}},
+								Value:   "madd",
+							},
+							{
+								Origins: []meta.Origin{{File: "file.yml", Line: 4, Col: 7}},
+								Value:   "tabby",
+							},
+						},
+					},
+					"other": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 5, Col: 1}},
+						Map: map[string]meta.Object{
+							"things": {
+								Origins: []meta.Origin{{File: "file.yml", Line: 6, Col: 5}},
+								Map: map[string]meta.Object{
+									"red": {
+										Origins: []meta.Origin{{File: "file.yml", Line: 7, Col: 14}},
+										Value:   "balloons",
+									},
+									"green": {
+										// Leave the origin off here to show what happens if none
+										// is present.
+										Array: []meta.Object{
+											{Value: "grass"},
+											{Value: "ground"},
+										},
+									},
+								},
+							},
+							"trending": {
+								Origins: []meta.Origin{{File: "file.yml", Line: 12, Col: 15}},
+								Value:   "now",
+							},
+						},
+					},
+					"servers": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 18, Col: 1}},
+						Array: []meta.Object{
+							{
+								Origins: []meta.Origin{{File: "file.yml", Line: 19, Col: 3}},
+								Map: map[string]meta.Object{
+									"name": {
+										Origins: []meta.Origin{{File: "file.yml", Line: 20, Col: 5}},
+										Value:   "alpha",
+									},
+								},
+							},
+							{
+								Origins: []meta.Origin{{File: "file.yml", Line: 21, Col: 3}},
+								Map: map[string]meta.Object{
+									"name": {
+										Origins: []meta.Origin{{File: "file.yml", Line: 22, Col: 5}},
+										Value:   "beta",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: `candy = "bar"
+cats = ["madd", "tabby"]
+
+[other]
+trending = "now"
+
+[other.things]
+green = ["grass", "ground"]
+red = "balloons"
+
+[[servers]]
+name = "alpha"
+
+[[servers]]
+name = "beta"
+`,
+			expectedExtended: `candy = "bar"                       # file.yml:1[8]
+cats = ["madd", "tabby"]            # file.yml:2[1]
+
+[other]                             # file.yml:5[1]
+trending = "now"                    # file.yml:12[15]
+
+[other.things]                      # file.yml:6[5]
+green = ["grass", "ground"]         # unknown
+red = "balloons"                    # file.yml:7[14]
+
+[[servers]]                         # file.yml:19[3]
+name = "alpha"                      # file.yml:20[5]
+
+[[servers]]                         # file.yml:21[3]
+name = "beta"                       # file.yml:22[5]
+`,
+		},
+		{
+			description: "A multi-line string whose content contains a comment-like sequence.",
+			in: meta.Object{
+				Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 1}},
+				Map: map[string]meta.Object{
+					"note": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 8}},
+						Value:   "a\nb # c",
+					},
+				},
+			},
+			expected: `note = """
+a
+b # c"""
+`,
+			expectedExtended: `note = """
+a
+b # c"""        # file.yml:1[8]
+`,
+		},
+		{
+			description: "try to encode a channel (invalid) for verifying the failure path",
+			in: meta.Object{
+				Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 1}},
+				Map: map[string]meta.Object{
+					"candy": {
+						Origins: []meta.Origin{{File: "file.yml", Line: 1, Col: 8}},
+						Value:   make(chan int),
+					},
+				},
+			},
+			expectedErr: ErrEncoding,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var e Encoder
+			got, err := e.EncodeExtended(tc.in)
+
+			if tc.expectedErr == nil {
+				assert.NoError(err)
+				assert.Empty(cmp.Diff(tc.expectedExtended, string(got)), "EncodeExtended(obj) failed")
+
+				if len(tc.in.Map) == 0 {
+					return
+				}
+
+				raw := tc.in.ToRaw()
+
+				got, err = e.Encode(raw)
+				assert.NoError(err)
+				assert.Empty(cmp.Diff(tc.expected, string(got)), "Encode(raw) failed")
+				return
+			}
+
+			assert.ErrorIs(err, tc.expectedErr)
+			assert.Nil(got)
+		})
+	}
+}
+
+func TestDecodeComment(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := decodeComment("#")
+	assert.Equal("", s)
+	assert.Error(err)
+}